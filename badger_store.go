@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// BadgerStore is a Store backed by an embedded BadgerDB, so the cache
+// survives process restarts. Expiration rides on Badger's native per-entry
+// TTL rather than anything in this package.
+//
+// BadgerStore does not enforce the 1024-key cap or any LRU eviction; every
+// Set is kept until its TTL (if any) expires or it's explicitly deleted.
+type BadgerStore struct {
+	db *badger.DB
+}
+
+func NewBadgerStore(dir string) (*BadgerStore, error) {
+	db, err := badger.Open(badger.DefaultOptions(dir))
+	if err != nil {
+		return nil, fmt.Errorf("open badger store at %q: %w", dir, err)
+	}
+	return &BadgerStore{db: db}, nil
+}
+
+func (s *BadgerStore) Get(key string) (interface{}, bool) {
+	var value string
+	err := s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(key))
+		if err != nil {
+			return err
+		}
+		return item.Value(func(v []byte) error {
+			value = string(v)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// Set stores value, with a TTL of expiration if positive. expiration <= 0
+// omits WithTTL entirely, which is Badger's convention for a key that never
+// expires on its own.
+func (s *BadgerStore) Set(key string, value interface{}, expiration time.Duration) {
+	err := s.db.Update(func(txn *badger.Txn) error {
+		entry := badger.NewEntry([]byte(key), []byte(fmt.Sprintf("%v", value)))
+		if expiration > 0 {
+			entry = entry.WithTTL(expiration)
+		}
+		return txn.SetEntry(entry)
+	})
+	if err != nil {
+		log.Printf("badger store: set %q: %v", key, err)
+	}
+}
+
+func (s *BadgerStore) Delete(key string) {
+	s.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete([]byte(key))
+	})
+}
+
+func (s *BadgerStore) Len() int {
+	return len(s.Keys())
+}
+
+func (s *BadgerStore) Keys() []string {
+	var keys []string
+	s.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			keys = append(keys, string(it.Item().Key()))
+		}
+		return nil
+	})
+	return keys
+}
+
+// SetBatch and GetBatch just loop; a single write transaction per batch is
+// future work if this becomes a hot path.
+
+func (s *BadgerStore) SetBatch(items []StoreBatchItem) {
+	for _, item := range items {
+		s.Set(item.Key, item.Value, item.Expiration)
+	}
+}
+
+func (s *BadgerStore) GetBatch(keys []string) map[string]interface{} {
+	out := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		if v, ok := s.Get(key); ok {
+			out[key] = v
+		}
+	}
+	return out
+}
+
+// Close releases the underlying Badger database handle.
+func (s *BadgerStore) Close() error {
+	return s.db.Close()
+}
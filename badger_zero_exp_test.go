@@ -0,0 +1,23 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBadgerStoreZeroExpiration(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewBadgerStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.db.Close()
+
+	s.Set("k", "v", 0)
+	time.Sleep(2 * time.Millisecond)
+	v, ok := s.Get("k")
+	t.Logf("badger Get after Set(exp=0): value=%v ok=%v (permanent key, never expires)", v, ok)
+	if !ok {
+		t.Fatalf("expected badger to store the key permanently when exp=0")
+	}
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"container/heap"
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Cache is a generic LRU cache with per-key expiration. Instead of a timer
+// per key, a single background sweeper goroutine wakes up when the next key
+// is due to expire and evicts it. Get also checks expiry inline so a key past
+// its TTL is never returned as a hit, even if the sweeper hasn't run yet.
+type Cache[K comparable, V any] struct {
+	mu    sync.Mutex // protects everything below
+	lru   *list.List // doubly linked list, most-recently-used at the front
+	cache map[K]element[K, V]
+	size  int // max size of cache
+
+	expiry  expiryHeap[K] // min-heap of pending expirations
+	wake    chan struct{} // nudges the sweeper when a sooner expiry is added
+	done    chan struct{} // closed by Close to stop the sweeper
+	closeMu sync.Once
+}
+
+type element[K comparable, V any] struct {
+	node      *list.Element // this key's node in c.lru (Value is the key)
+	value     V             // cached value
+	expiresAt time.Time     // when this entry should be evicted
+}
+
+// expiryItem is one entry in the expiry heap. index tracks its current
+// position so heap.Fix/heap.Remove can update or drop it in place; byKey on
+// the containing expiryHeap keeps exactly one live item per key, so a
+// re-Set updates that item instead of leaving a stale duplicate behind for
+// evictExpired to filter out later (which let the heap grow without bound
+// under repeated Sets of the same small key set).
+type expiryItem[K comparable] struct {
+	key       K
+	expiresAt time.Time
+	index     int
+}
+
+type expiryHeap[K comparable] struct {
+	items []*expiryItem[K]
+	byKey map[K]*expiryItem[K]
+}
+
+func (h expiryHeap[K]) Len() int           { return len(h.items) }
+func (h expiryHeap[K]) Less(i, j int) bool { return h.items[i].expiresAt.Before(h.items[j].expiresAt) }
+func (h expiryHeap[K]) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.items[i].index = i
+	h.items[j].index = j
+}
+func (h *expiryHeap[K]) Push(x interface{}) {
+	item := x.(*expiryItem[K])
+	item.index = len(h.items)
+	h.items = append(h.items, item)
+	h.byKey[item.key] = item
+}
+func (h *expiryHeap[K]) Pop() interface{} {
+	old := h.items
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	h.items = old[:n-1]
+	delete(h.byKey, item.key)
+	return item
+}
+
+func NewCache[K comparable, V any](size int) *Cache[K, V] {
+	c := &Cache[K, V]{
+		lru:   list.New(),
+		cache: make(map[K]element[K, V]),
+		size:  size,
+		expiry: expiryHeap[K]{
+			byKey: make(map[K]*expiryItem[K]),
+		},
+		wake: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+	go c.sweep()
+	return c
+}
+
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.getLocked(key)
+}
+
+func (c *Cache[K, V]) getLocked(key K) (V, bool) {
+	e, ok := c.cache[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	if !e.expiresAt.IsZero() && time.Now().After(e.expiresAt) {
+		c.removeLocked(key, e)
+		var zero V
+		return zero, false
+	}
+
+	c.lru.MoveToFront(e.node)
+	return e.value, true
+}
+
+func (c *Cache[K, V]) Set(key K, value V, expiration time.Duration) {
+	c.mu.Lock()
+	c.setLocked(key, value, expiration)
+	c.mu.Unlock()
+
+	c.nudge()
+}
+
+// setLocked stores value under key. expiration <= 0 means "keep forever":
+// expiresAt is left as the zero time.Time, which getLocked and evictExpired
+// both treat as "never expires" (matching BadgerStore's WithTTL convention),
+// and the key is left off the expiry heap entirely since it has nothing to
+// sweep.
+func (c *Cache[K, V]) setLocked(key K, value V, expiration time.Duration) {
+	var expiresAt time.Time
+	if expiration > 0 {
+		expiresAt = time.Now().Add(expiration)
+	}
+
+	if e, ok := c.cache[key]; ok {
+		c.lru.MoveToFront(e.node) // move to front
+		e.value = value
+		e.expiresAt = expiresAt
+		c.cache[key] = e
+	} else {
+		if c.lru.Len() >= c.size { // if cache is full
+			c.evict() // evict
+		}
+		node := c.lru.PushFront(key) // push to front
+		c.cache[key] = element[K, V]{node: node, value: value, expiresAt: expiresAt}
+	}
+
+	c.syncExpiryLocked(key, expiresAt)
+}
+
+// syncExpiryLocked keeps key's entry in the expiry heap in sync with
+// expiresAt: updates an existing entry in place via heap.Fix, pushes a new
+// one if key didn't have one, or drops it if expiresAt is zero (no expiry).
+// c.mu must be held.
+func (c *Cache[K, V]) syncExpiryLocked(key K, expiresAt time.Time) {
+	item, has := c.expiry.byKey[key]
+	switch {
+	case has && expiresAt.IsZero():
+		heap.Remove(&c.expiry, item.index)
+	case has:
+		item.expiresAt = expiresAt
+		heap.Fix(&c.expiry, item.index)
+	case !expiresAt.IsZero():
+		heap.Push(&c.expiry, &expiryItem[K]{key: key, expiresAt: expiresAt})
+	}
+}
+
+// GetBatch looks up keys, acquiring the mutex once for the whole batch
+// instead of once per key. Missing/expired keys are simply absent from the
+// result.
+func (c *Cache[K, V]) GetBatch(keys []K) map[K]V {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	out := make(map[K]V, len(keys))
+	for _, key := range keys {
+		if v, ok := c.getLocked(key); ok {
+			out[key] = v
+		}
+	}
+	return out
+}
+
+// BatchItem is one key/value/expiration triple for SetBatch.
+type BatchItem[K comparable, V any] struct {
+	Key        K
+	Value      V
+	Expiration time.Duration
+}
+
+// SetBatch sets every item, acquiring the mutex once for the whole batch.
+func (c *Cache[K, V]) SetBatch(items []BatchItem[K, V]) {
+	c.mu.Lock()
+	for _, item := range items {
+		c.setLocked(item.Key, item.Value, item.Expiration)
+	}
+	c.mu.Unlock()
+
+	c.nudge()
+}
+
+func (c *Cache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.cache[key]; ok {
+		c.removeLocked(key, e)
+	}
+}
+
+// removeLocked removes key from the list, map, and expiry heap. c.mu must
+// be held.
+func (c *Cache[K, V]) removeLocked(key K, e element[K, V]) {
+	c.lru.Remove(e.node)
+	delete(c.cache, key)
+	if item, ok := c.expiry.byKey[key]; ok {
+		heap.Remove(&c.expiry, item.index)
+	}
+}
+
+func (c *Cache[K, V]) evict() {
+	e := c.lru.Back() // get last element
+	if e != nil {
+		key := e.Value.(K)
+		c.lru.Remove(e)      // remove from list
+		delete(c.cache, key) // delete from cache
+		if item, ok := c.expiry.byKey[key]; ok {
+			heap.Remove(&c.expiry, item.index)
+		}
+	}
+}
+
+// Len returns the number of keys currently in the cache.
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Keys returns the cached keys, most-recently-used first.
+func (c *Cache[K, V]) Keys() []K {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	keys := make([]K, 0, c.lru.Len())
+	for e := c.lru.Front(); e != nil; e = e.Next() {
+		keys = append(keys, e.Value.(K))
+	}
+	return keys
+}
+
+// Close stops the sweeper goroutine. The cache is unusable afterwards.
+func (c *Cache[K, V]) Close() {
+	c.closeMu.Do(func() {
+		close(c.done)
+	})
+}
+
+// nudge wakes the sweeper so it can reconsider its sleep deadline after a
+// Set that may have landed a sooner expiry at the top of the heap.
+func (c *Cache[K, V]) nudge() {
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// sweep runs in its own goroutine for the lifetime of the cache, sleeping
+// until the next expiry and then batch-evicting everything that's due.
+func (c *Cache[K, V]) sweep() {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		c.mu.Lock()
+		var next time.Duration
+		if c.expiry.Len() == 0 {
+			next = time.Hour
+		} else {
+			next = time.Until(c.expiry.items[0].expiresAt)
+		}
+		c.mu.Unlock()
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(next)
+
+		select {
+		case <-c.done:
+			return
+		case <-c.wake:
+			continue
+		case <-timer.C:
+			c.evictExpired()
+		}
+	}
+}
+
+// evictExpired pops and deletes every heap entry that is due. Since
+// syncExpiryLocked keeps at most one live heap entry per key, every popped
+// item here corresponds to a real, currently-expired cache entry.
+func (c *Cache[K, V]) evictExpired() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for c.expiry.Len() > 0 && !c.expiry.items[0].expiresAt.After(now) {
+		item := heap.Pop(&c.expiry).(*expiryItem[K])
+
+		if e, ok := c.cache[item.key]; ok {
+			c.removeLocked(item.key, e)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"container/list"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// timerCache is the old per-key time.AfterFunc design, kept only so we can
+// benchmark it against the heap-sweeper Cache in cache.go.
+type timerCache struct {
+	mu    sync.Mutex
+	lru   *list.List
+	cache map[string]timerElement
+	size  int
+}
+
+type timerElement struct {
+	node  *list.Element
+	value interface{}
+	timer *time.Timer
+}
+
+func newTimerCache(size int) *timerCache {
+	return &timerCache{
+		lru:   list.New(),
+		cache: make(map[string]timerElement),
+		size:  size,
+	}
+}
+
+func (c *timerCache) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.cache[key]; ok {
+		c.lru.MoveToFront(e.node)
+		return e.value, true
+	}
+	return nil, false
+}
+
+func (c *timerCache) Set(key string, value interface{}, expiration time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.cache[key]; ok {
+		c.lru.MoveToFront(e.node)
+		e.timer.Stop()
+		e.value = value
+		e.timer = time.AfterFunc(expiration, func() { c.Delete(key) })
+		c.cache[key] = e
+		return
+	}
+
+	if c.lru.Len() >= c.size {
+		if back := c.lru.Back(); back != nil {
+			c.lru.Remove(back)
+			delete(c.cache, back.Value.(string))
+		}
+	}
+
+	node := c.lru.PushFront(key)
+	c.cache[key] = timerElement{node: node, value: value, timer: time.AfterFunc(expiration, func() {
+		c.Delete(key)
+	})}
+}
+
+func (c *timerCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.cache[key]; ok {
+		c.lru.Remove(e.node)
+		e.timer.Stop()
+		delete(c.cache, key)
+	}
+}
+
+func BenchmarkTimerPerKeySetGet(b *testing.B) {
+	c := newTimerCache(1024)
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			key := strconv.Itoa(i % 1024)
+			c.Set(key, i, time.Minute)
+			c.Get(key)
+			i++
+		}
+	})
+}
+
+func BenchmarkHeapSweeperSetGet(b *testing.B) {
+	c := NewCache[string, int](1024)
+	defer c.Close()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			key := strconv.Itoa(i % 1024)
+			c.Set(key, i, time.Minute)
+			c.Get(key)
+			i++
+		}
+	})
+}
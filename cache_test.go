@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCacheSetBatchGetBatch(t *testing.T) {
+	c := NewCache[string, interface{}](1024)
+	defer c.Close()
+
+	c.SetBatch([]BatchItem[string, interface{}]{
+		{Key: "a", Value: "1", Expiration: time.Minute},
+		{Key: "b", Value: "2", Expiration: time.Minute},
+	})
+
+	got := c.GetBatch([]string{"a", "b", "missing"})
+	if len(got) != 2 {
+		t.Fatalf("expected 2 hits, got %d: %v", len(got), got)
+	}
+	if got["a"] != "1" || got["b"] != "2" {
+		t.Fatalf("unexpected values: %v", got)
+	}
+}
+
+func TestCacheGetExpired(t *testing.T) {
+	c := NewCache[string, interface{}](1024)
+	defer c.Close()
+
+	c.Set("a", "1", time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+
+	if _, ok := c.Get("a"); ok {
+		t.Fatalf("expected expired key to be a miss")
+	}
+}
+
+func TestCacheSetChurnKeepsExpiryHeapBounded(t *testing.T) {
+	c := NewCache[string, interface{}](1024)
+	defer c.Close()
+
+	for i := 0; i < 100_000; i++ {
+		c.Set("k", "v", time.Hour)
+	}
+
+	c.mu.Lock()
+	heapLen := c.expiry.Len()
+	c.mu.Unlock()
+
+	if heapLen != 1 {
+		t.Fatalf("expected repeated Sets on one key to update its expiry entry in place, got %d heap entries", heapLen)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CORSConfig replaces the old corsMiddleware, which unconditionally echoed
+// "Access-Control-Allow-Origin: *". It supports an origin allowlist (exact
+// strings and "*.example.com" wildcard suffixes), per-route methods/headers,
+// credentialed requests, and preflight caching.
+type CORSConfig struct {
+	AllowedOrigins   []string `json:"allowedOrigins" yaml:"allowedOrigins"`
+	AllowedMethods   []string `json:"allowedMethods" yaml:"allowedMethods"`
+	AllowedHeaders   []string `json:"allowedHeaders" yaml:"allowedHeaders"`
+	ExposedHeaders   []string `json:"exposedHeaders" yaml:"exposedHeaders"`
+	AllowCredentials bool     `json:"allowCredentials" yaml:"allowCredentials"`
+	MaxAge           int      `json:"maxAge" yaml:"maxAge"` // seconds, for Access-Control-Max-Age
+
+	// Routes overrides AllowedMethods/AllowedHeaders for specific request
+	// paths (exact match against r.URL.Path), e.g. a route that only ever
+	// needs GET shouldn't advertise POST in its preflight response. Origin
+	// allowlisting, credentials, and MaxAge stay global since they're
+	// properties of the deployment, not any one route.
+	Routes map[string]*RouteCORSConfig `json:"routes" yaml:"routes"`
+}
+
+// RouteCORSConfig narrows the methods/headers a single route's preflight
+// response advertises. Either field may be left nil to fall back to the
+// top-level CORSConfig's value for that field.
+type RouteCORSConfig struct {
+	AllowedMethods []string `json:"allowedMethods" yaml:"allowedMethods"`
+	AllowedHeaders []string `json:"allowedHeaders" yaml:"allowedHeaders"`
+}
+
+// DefaultCORSConfig only allows localhost, which is enough for local
+// development against the React app without ever emitting ACAO: *.
+func DefaultCORSConfig() *CORSConfig {
+	return &CORSConfig{
+		AllowedOrigins: []string{"http://localhost:3000"},
+		AllowedMethods: []string{"GET", "POST", "OPTIONS"},
+		AllowedHeaders: []string{"Content-Type", "Authorization"},
+		MaxAge:         600,
+	}
+}
+
+// LoadCORSConfig reads a CORSConfig from a JSON or YAML file, chosen by
+// extension. Pass "" to skip loading and use DefaultCORSConfig.
+func LoadCORSConfig(path string) (*CORSConfig, error) {
+	if path == "" {
+		return DefaultCORSConfig(), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read cors config %q: %w", path, err)
+	}
+
+	cfg := &CORSConfig{}
+	switch {
+	case strings.HasSuffix(path, ".yaml"), strings.HasSuffix(path, ".yml"):
+		err = yaml.Unmarshal(data, cfg)
+	default:
+		err = json.Unmarshal(data, cfg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse cors config %q: %w", path, err)
+	}
+	return cfg, nil
+}
+
+func (c *CORSConfig) originAllowed(origin string) bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == origin {
+			return true
+		}
+		if strings.HasPrefix(allowed, "*.") {
+			suffix := allowed[1:] // ".example.com"
+			if strings.HasSuffix(origin, suffix) && origin != suffix {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// routeCORSStrings is the precomputed, comma-joined form of a
+// RouteCORSConfig (or the top-level CORSConfig, for the fallback entry), so
+// corsMiddleware never re-joins a slice on the request path.
+type routeCORSStrings struct {
+	allowedMethods string
+	allowedHeaders string
+}
+
+// corsMiddleware enforces c against every request. Disallowed origins get no
+// Access-Control-Allow-Origin header at all, rather than a wildcard.
+func (c *CORSConfig) corsMiddleware(next http.Handler) http.Handler {
+	fallback := routeCORSStrings{
+		allowedMethods: strings.Join(c.AllowedMethods, ", "),
+		allowedHeaders: strings.Join(c.AllowedHeaders, ", "),
+	}
+	exposedHeaders := strings.Join(c.ExposedHeaders, ", ")
+
+	routes := make(map[string]routeCORSStrings, len(c.Routes))
+	for path, route := range c.Routes {
+		s := fallback
+		if route.AllowedMethods != nil {
+			s.allowedMethods = strings.Join(route.AllowedMethods, ", ")
+		}
+		if route.AllowedHeaders != nil {
+			s.allowedHeaders = strings.Join(route.AllowedHeaders, ", ")
+		}
+		routes[path] = s
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		allowed := origin != "" && c.originAllowed(origin)
+
+		if allowed {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Add("Vary", "Origin")
+			if c.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+			if exposedHeaders != "" {
+				w.Header().Set("Access-Control-Expose-Headers", exposedHeaders)
+			}
+		}
+
+		if r.Method == http.MethodOptions {
+			if allowed {
+				s, ok := routes[r.URL.Path]
+				if !ok {
+					s = fallback
+				}
+				w.Header().Set("Access-Control-Allow-Methods", s.allowedMethods)
+				w.Header().Set("Access-Control-Allow-Headers", s.allowedHeaders)
+				if c.MaxAge > 0 {
+					w.Header().Set("Access-Control-Max-Age", strconv.Itoa(c.MaxAge))
+				}
+			}
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
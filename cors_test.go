@@ -0,0 +1,53 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORSMiddlewarePerRouteMethods(t *testing.T) {
+	cfg := &CORSConfig{
+		AllowedOrigins: []string{"http://localhost:3000"},
+		AllowedMethods: []string{"GET", "POST", "OPTIONS"},
+		AllowedHeaders: []string{"Content-Type"},
+		Routes: map[string]*RouteCORSConfig{
+			"/get": {AllowedMethods: []string{"GET", "OPTIONS"}},
+		},
+	}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := cfg.corsMiddleware(next)
+
+	preflight := func(path string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodOptions, path, nil)
+		r.Header.Set("Origin", "http://localhost:3000")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	w := preflight("/get")
+	if got := w.Header().Get("Access-Control-Allow-Methods"); got != "GET, OPTIONS" {
+		t.Fatalf("expected /get's narrowed methods, got %q", got)
+	}
+
+	w = preflight("/set")
+	if got := w.Header().Get("Access-Control-Allow-Methods"); got != "GET, POST, OPTIONS" {
+		t.Fatalf("expected /set to fall back to the top-level methods, got %q", got)
+	}
+}
+
+func TestCORSMiddlewareDisallowedOriginGetsNoHeaders(t *testing.T) {
+	cfg := DefaultCORSConfig()
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := cfg.corsMiddleware(next)
+
+	r := httptest.NewRequest(http.MethodGet, "/get", nil)
+	r.Header.Set("Origin", "http://evil.example.com")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Fatalf("expected no ACAO header for a disallowed origin, got %q", got)
+	}
+}
@@ -0,0 +1,234 @@
+// Package distributed propagates Set/Delete events across cache instances so
+// a multi-pod deployment stays coherent without all of them sharing a single
+// backend (e.g. several in-memory Cache instances behind a load balancer).
+package distributed
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"sync"
+	"time"
+)
+
+// EventType is the kind of cache mutation being propagated.
+type EventType string
+
+const (
+	EventSet    EventType = "SET"
+	EventDelete EventType = "DELETE"
+	// eventAck is published by a node after it applies a remote Set/Delete,
+	// so the originator can count acks for WriteThrough. It never reaches
+	// Applier.Set/Delete.
+	eventAck EventType = "ACK"
+)
+
+// Event is one cache mutation, tagged with the node that originated it so
+// other nodes can apply it and the originating node can skip its own echo.
+// AckNodeID/AckSeq are only set on eventAck events, naming the original
+// event being acknowledged.
+type Event struct {
+	Type       EventType
+	Key        string
+	Value      interface{}
+	Expiration time.Duration
+	NodeID     string
+	Seq        uint64
+
+	AckNodeID string
+	AckSeq    uint64
+}
+
+// Transport moves Events between nodes. Redis Pub/Sub and NATS both satisfy
+// this with a handful of lines; see redis_transport.go / nats_transport.go.
+type Transport interface {
+	Publish(ctx context.Context, event Event) error
+	Subscribe(ctx context.Context) (<-chan Event, error)
+	Close() error
+}
+
+// Applier is the local cache that remote events get applied to. *Cache
+// already satisfies this shape.
+type Applier interface {
+	Set(key string, value interface{}, expiration time.Duration)
+	Delete(key string)
+}
+
+// WriteMode controls how long Node.Set blocks before returning.
+type WriteMode int
+
+const (
+	// WriteBehind returns as soon as the local cache is updated; the
+	// broadcast happens in the background.
+	WriteBehind WriteMode = iota
+	// WriteThrough waits for AckQuorum other nodes to acknowledge the event
+	// before returning.
+	WriteThrough
+)
+
+const replayBufferSize = 256
+
+// Node wires a local Applier to a Transport, replicating writes out and
+// applying writes that come in, while skipping events it originated itself.
+type Node struct {
+	ID        string
+	transport Transport
+	local     Applier
+	Mode      WriteMode
+	AckQuorum int
+
+	mu       sync.Mutex
+	seq      uint64
+	acks     map[uint64]chan struct{}
+	ackCount map[uint64]int
+	replay   []Event // bounded ring buffer of recently applied events
+}
+
+// NewNode creates a Node with a random ID that tags every event it publishes.
+func NewNode(local Applier, transport Transport) *Node {
+	return &Node{
+		ID:        randomID(),
+		transport: transport,
+		local:     local,
+		Mode:      WriteBehind,
+		acks:      make(map[uint64]chan struct{}),
+		ackCount:  make(map[uint64]int),
+	}
+}
+
+func randomID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Run subscribes to the transport and applies incoming events until ctx is
+// canceled. It reconnects with exponential backoff on subscribe errors.
+func (n *Node) Run(ctx context.Context) {
+	backoff := time.Second
+	for {
+		events, err := n.transport.Subscribe(ctx)
+		if err != nil {
+			log.Printf("distributed: subscribe failed, retrying in %s: %v", backoff, err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			if backoff < 30*time.Second {
+				backoff *= 2
+			}
+			continue
+		}
+		backoff = time.Second
+
+	readLoop:
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-events:
+				if !ok {
+					break readLoop // transport dropped us; resubscribe
+				}
+				n.handle(event)
+			}
+		}
+	}
+}
+
+func (n *Node) handle(event Event) {
+	if event.Type == eventAck {
+		n.recordAck(event)
+		return
+	}
+
+	if event.NodeID == n.ID {
+		return // our own echo
+	}
+
+	switch event.Type {
+	case EventSet:
+		n.local.Set(event.Key, event.Value, event.Expiration)
+	case EventDelete:
+		n.local.Delete(event.Key)
+	}
+
+	n.mu.Lock()
+	n.replay = append(n.replay, event)
+	if len(n.replay) > replayBufferSize {
+		n.replay = n.replay[len(n.replay)-replayBufferSize:]
+	}
+	n.mu.Unlock()
+
+	n.transport.Publish(context.Background(), Event{
+		Type:      eventAck,
+		NodeID:    n.ID,
+		AckNodeID: event.NodeID,
+		AckSeq:    event.Seq,
+	})
+}
+
+// recordAck counts an ack toward one of our own in-flight WriteThrough
+// broadcasts, and wakes the waiter once AckQuorum has been reached.
+func (n *Node) recordAck(event Event) {
+	if event.AckNodeID != n.ID {
+		return // ack for an event some other node originated
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	ch, ok := n.acks[event.AckSeq]
+	if !ok {
+		return
+	}
+	n.ackCount[event.AckSeq]++
+	if n.ackCount[event.AckSeq] >= n.AckQuorum {
+		close(ch)
+		delete(n.acks, event.AckSeq)
+		delete(n.ackCount, event.AckSeq)
+	}
+}
+
+// Set applies value locally and broadcasts it. In WriteThrough mode it
+// blocks until AckQuorum remote nodes have applied the event or ctx expires.
+func (n *Node) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	n.local.Set(key, value, expiration)
+	return n.broadcast(ctx, Event{Type: EventSet, Key: key, Value: value, Expiration: expiration})
+}
+
+// Delete removes key locally and broadcasts the deletion.
+func (n *Node) Delete(ctx context.Context, key string) error {
+	n.local.Delete(key)
+	return n.broadcast(ctx, Event{Type: EventDelete, Key: key})
+}
+
+func (n *Node) broadcast(ctx context.Context, event Event) error {
+	n.mu.Lock()
+	n.seq++
+	event.NodeID = n.ID
+	event.Seq = n.seq
+
+	var wait chan struct{}
+	if n.Mode == WriteThrough && n.AckQuorum > 0 {
+		wait = make(chan struct{})
+		n.acks[event.Seq] = wait
+	}
+	n.mu.Unlock()
+
+	if err := n.transport.Publish(ctx, event); err != nil {
+		return err
+	}
+
+	if wait == nil {
+		return nil
+	}
+	select {
+	case <-wait:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,69 @@
+package distributed
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NatsTransport moves Events over a single NATS subject.
+type NatsTransport struct {
+	conn    *nats.Conn
+	subject string
+}
+
+func NewNatsTransport(url, subject string) (*NatsTransport, error) {
+	conn, err := nats.Connect(url, nats.RetryOnFailedConnect(true), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, err
+	}
+	return &NatsTransport{conn: conn, subject: subject}, nil
+}
+
+func (t *NatsTransport) Publish(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return t.conn.Publish(t.subject, payload)
+}
+
+func (t *NatsTransport) Subscribe(ctx context.Context) (<-chan Event, error) {
+	msgs := make(chan *nats.Msg, 64)
+	sub, err := t.conn.ChanSubscribe(t.subject, msgs)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-msgs:
+				if !ok {
+					return
+				}
+				var event Event
+				if err := json.Unmarshal(msg.Data, &event); err != nil {
+					continue
+				}
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+func (t *NatsTransport) Close() error {
+	t.conn.Close()
+	return nil
+}
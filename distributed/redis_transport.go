@@ -0,0 +1,57 @@
+package distributed
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisTransport moves Events over a single Redis Pub/Sub channel.
+type RedisTransport struct {
+	rdb     *redis.Client
+	channel string
+}
+
+func NewRedisTransport(addr, channel string) *RedisTransport {
+	return &RedisTransport{
+		rdb:     redis.NewClient(&redis.Options{Addr: addr}),
+		channel: channel,
+	}
+}
+
+func (t *RedisTransport) Publish(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return t.rdb.Publish(ctx, t.channel, payload).Err()
+}
+
+func (t *RedisTransport) Subscribe(ctx context.Context) (<-chan Event, error) {
+	sub := t.rdb.Subscribe(ctx, t.channel)
+	if _, err := sub.Receive(ctx); err != nil {
+		return nil, err
+	}
+
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		for msg := range sub.Channel() {
+			var event Event
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				continue
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+func (t *RedisTransport) Close() error {
+	return t.rdb.Close()
+}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CacheKeyFunc builds the cache key for an incoming request. Swap it out to
+// key on things other than method+URL (e.g. a tenant header).
+type CacheKeyFunc func(*http.Request) string
+
+// CacheEntry is what actually gets stored in the Cache for a cached
+// response: status code, headers, and the buffered body.
+type CacheEntry struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// HTTPCache is an http.Handler that sits in front of an upstream handler and
+// serves cached responses out of a Cache, RFC 7234-style.
+type HTTPCache struct {
+	cache      Store
+	upstream   http.Handler
+	CacheKey   CacheKeyFunc  // defaults to defaultCacheKey
+	DefaultTTL time.Duration // used when the response has no max-age/Expires
+}
+
+// NewHTTPCache wraps upstream with a response cache backed by cache.
+func NewHTTPCache(cache Store, upstream http.Handler) *HTTPCache {
+	return &HTTPCache{
+		cache:      cache,
+		upstream:   upstream,
+		CacheKey:   defaultCacheKey,
+		DefaultTTL: 60 * time.Second,
+	}
+}
+
+func defaultCacheKey(r *http.Request) string {
+	return r.Method + " " + r.URL.String()
+}
+
+func (h *HTTPCache) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		h.upstream.ServeHTTP(w, r)
+		return
+	}
+
+	if requestDirectives(r.Header.Get("Cache-Control"))["no-store"] {
+		h.upstream.ServeHTTP(w, r)
+		return
+	}
+
+	baseKey := h.CacheKey(r)
+	key := h.effectiveKey(baseKey, r)
+	noCache := requestDirectives(r.Header.Get("Cache-Control"))["no-cache"]
+
+	if entry, expiresAt, ok := h.lookup(key); ok && !noCache {
+		if time.Now().Before(expiresAt) {
+			writeEntry(w, entry, true)
+			return
+		}
+		// Stale: try to revalidate with the upstream before serving it.
+		if revalidated, fresh := h.revalidate(r, entry); revalidated {
+			if fresh != nil {
+				h.store(baseKey, fresh, r)
+				writeEntry(w, fresh, false)
+			} else {
+				// 304 Not Modified: entry is still good, just refresh the clock.
+				h.cache.Set(key, entry, h.ttlFor(entry.Header))
+				writeEntry(w, entry, true)
+			}
+			return
+		}
+	}
+
+	rec := &bufferingResponseWriter{header: make(http.Header), statusCode: http.StatusOK}
+	h.upstream.ServeHTTP(rec, r)
+
+	entry := &CacheEntry{
+		StatusCode: rec.statusCode,
+		Header:     rec.header,
+		Body:       rec.body.Bytes(),
+	}
+
+	if cacheable(r, entry) {
+		h.store(baseKey, entry, r)
+	}
+
+	writeEntry(w, entry, false)
+}
+
+// varyMarkerSuffix is appended to a request's base cache key to store the
+// Vary header value of whatever response was last cached for it. A plain
+// lookup under the base key can't know a response varies until it's seen
+// one, so effectiveKey checks this marker first to find the right
+// Vary-folded key before the response has even come back from upstream.
+const varyMarkerSuffix = "\x00vary"
+
+// effectiveKey returns the key a request should actually be looked up
+// under: baseKey folded with whatever Vary value store() last recorded for
+// it, or baseKey itself if nothing's cached yet or the cached response
+// didn't vary.
+func (h *HTTPCache) effectiveKey(baseKey string, r *http.Request) string {
+	v, ok := h.cache.Get(baseKey + varyMarkerSuffix)
+	if !ok {
+		return baseKey
+	}
+	vary, ok := v.(string)
+	if !ok || vary == "" {
+		return baseKey
+	}
+	return varyKey(baseKey, vary, r)
+}
+
+// store saves entry under baseKey, honoring Vary by folding the named
+// request headers into the key and recording the Vary value itself under
+// baseKey+varyMarkerSuffix so a later request's effectiveKey can find it
+// before it has a response to read Vary off of.
+func (h *HTTPCache) store(baseKey string, entry *CacheEntry, r *http.Request) {
+	ttl := h.ttlFor(entry.Header)
+	vary := entry.Header.Get("Vary")
+	key := baseKey
+	if vary != "" {
+		h.cache.Set(baseKey+varyMarkerSuffix, vary, ttl)
+		key = varyKey(baseKey, vary, r)
+	}
+	h.cache.Set(key, entry, ttl)
+}
+
+func (h *HTTPCache) lookup(key string) (*CacheEntry, time.Time, bool) {
+	v, ok := h.cache.Get(key)
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	entry, ok := v.(*CacheEntry)
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	return entry, h.expiresAt(entry.Header), true
+}
+
+// revalidate asks the upstream to confirm a stale entry is still good. It
+// returns handled=false if the upstream didn't answer with a usable 304/200.
+func (h *HTTPCache) revalidate(r *http.Request, entry *CacheEntry) (handled bool, fresh *CacheEntry) {
+	etag := entry.Header.Get("ETag")
+	lastMod := entry.Header.Get("Last-Modified")
+	if etag == "" && lastMod == "" {
+		return false, nil
+	}
+
+	revReq := r.Clone(r.Context())
+	if etag != "" {
+		revReq.Header.Set("If-None-Match", etag)
+	}
+	if lastMod != "" {
+		revReq.Header.Set("If-Modified-Since", lastMod)
+	}
+
+	rec := &bufferingResponseWriter{header: make(http.Header), statusCode: http.StatusOK}
+	h.upstream.ServeHTTP(rec, revReq)
+
+	if rec.statusCode == http.StatusNotModified {
+		return true, nil
+	}
+	if rec.statusCode == http.StatusOK {
+		return true, &CacheEntry{StatusCode: rec.statusCode, Header: rec.header, Body: rec.body.Bytes()}
+	}
+	return false, nil
+}
+
+// ttlFor derives the TTL to cache header under for from max-age or Expires,
+// then subtracts Age (floored at 0) so a response that arrived already
+// partly stale at an upstream cache doesn't get the full TTL re-applied
+// here. Age only applies once a max-age/Expires-derived TTL was found;
+// h.DefaultTTL is a fallback for responses with neither and isn't aged.
+func (h *HTTPCache) ttlFor(header http.Header) time.Duration {
+	var ttl time.Duration
+	var found bool
+
+	if d := requestDirectives(header.Get("Cache-Control")); d["max-age"] {
+		if t, ok := d.maxAge(); ok {
+			ttl, found = t, true
+		}
+	}
+	if !found {
+		if exp := header.Get("Expires"); exp != "" {
+			if t, err := http.ParseTime(exp); err == nil {
+				if t2 := time.Until(t); t2 > 0 {
+					ttl, found = t2, true
+				}
+			}
+		}
+	}
+	if !found {
+		return h.DefaultTTL
+	}
+
+	if age, ok := parseAge(header.Get("Age")); ok {
+		ttl -= age
+		if ttl < 0 {
+			ttl = 0
+		}
+	}
+	return ttl
+}
+
+// parseAge parses an Age header value (non-negative integer seconds, per
+// RFC 7234 §5.1) into a Duration.
+func parseAge(age string) (time.Duration, bool) {
+	if age == "" {
+		return 0, false
+	}
+	secs, err := strconv.Atoi(age)
+	if err != nil || secs < 0 {
+		return 0, false
+	}
+	return time.Duration(secs) * time.Second, true
+}
+
+func (h *HTTPCache) expiresAt(header http.Header) time.Time {
+	return time.Now().Add(h.ttlFor(header))
+}
+
+// writeEntry replays entry to w, adding X-From-Cache: 1 only when fromCache
+// is set, i.e. when the response comes out of the store rather than upstream.
+func writeEntry(w http.ResponseWriter, entry *CacheEntry, fromCache bool) {
+	for k, vs := range entry.Header {
+		for _, v := range vs {
+			w.Header().Add(k, v)
+		}
+	}
+	if fromCache {
+		w.Header().Set("X-From-Cache", "1")
+	}
+	w.WriteHeader(entry.StatusCode)
+	w.Write(entry.Body)
+}
+
+func cacheable(r *http.Request, entry *CacheEntry) bool {
+	if entry.StatusCode != http.StatusOK {
+		return false
+	}
+	cc := cacheControlDirectives(entry.Header.Get("Cache-Control"))
+	if cc["no-store"] || cc["private"] || cc["no-cache"] {
+		return false
+	}
+	if requestDirectives(r.Header.Get("Cache-Control"))["no-store"] {
+		return false
+	}
+	return true
+}
+
+// varyKey folds the request header values named in a Vary header into the
+// cache key so that different representations don't collide.
+func varyKey(key, vary string, r *http.Request) string {
+	if vary == "" {
+		return key
+	}
+	names := strings.Split(vary, ",")
+	for i := range names {
+		names[i] = strings.TrimSpace(names[i])
+	}
+	sort.Strings(names)
+
+	h := sha1.New()
+	for _, name := range names {
+		h.Write([]byte(name))
+		h.Write([]byte{0})
+		h.Write([]byte(r.Header.Get(name)))
+		h.Write([]byte{0})
+	}
+	return key + "#vary=" + hex.EncodeToString(h.Sum(nil))
+}
+
+// directiveSet is a parsed Cache-Control header.
+type directiveSet map[string]bool
+
+func (d directiveSet) maxAge() (time.Duration, bool) {
+	for k := range d {
+		if strings.HasPrefix(k, "max-age=") {
+			secs, err := strconv.Atoi(strings.TrimPrefix(k, "max-age="))
+			if err != nil || secs < 0 {
+				return 0, false
+			}
+			return time.Duration(secs) * time.Second, true
+		}
+	}
+	return 0, false
+}
+
+func requestDirectives(header string) directiveSet {
+	return cacheControlDirectives(header)
+}
+
+func cacheControlDirectives(header string) directiveSet {
+	d := make(directiveSet)
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(strings.ToLower(part))
+		if part == "" {
+			continue
+		}
+		if strings.HasPrefix(part, "max-age=") {
+			d[part] = true
+			d["max-age"] = true
+			continue
+		}
+		d[part] = true
+	}
+	return d
+}
+
+// bufferingResponseWriter captures an upstream handler's response so it can
+// be cached and replayed.
+type bufferingResponseWriter struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+	wroteHead  bool
+}
+
+func (b *bufferingResponseWriter) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferingResponseWriter) WriteHeader(statusCode int) {
+	if b.wroteHead {
+		return
+	}
+	b.statusCode = statusCode
+	b.wroteHead = true
+}
+
+func (b *bufferingResponseWriter) Write(p []byte) (int, error) {
+	if !b.wroteHead {
+		b.WriteHeader(http.StatusOK)
+	}
+	return b.body.Write(p)
+}
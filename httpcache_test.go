@@ -0,0 +1,137 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestHTTPCacheHitMiss(t *testing.T) {
+	var upstreamCalls int
+	upstream := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamCalls++
+		w.Write([]byte("hello"))
+	})
+
+	store := NewCache[string, interface{}](1024)
+	defer store.Close()
+	hc := NewHTTPCache(store, upstream)
+
+	r := httptest.NewRequest(http.MethodGet, "/widget", nil)
+	w := httptest.NewRecorder()
+	hc.ServeHTTP(w, r)
+	if upstreamCalls != 1 {
+		t.Fatalf("expected first request to hit upstream, got %d calls", upstreamCalls)
+	}
+	if w.Body.String() != "hello" {
+		t.Fatalf("unexpected body: %q", w.Body.String())
+	}
+
+	w2 := httptest.NewRecorder()
+	hc.ServeHTTP(w2, httptest.NewRequest(http.MethodGet, "/widget", nil))
+	if upstreamCalls != 1 {
+		t.Fatalf("expected second request to be served from cache, got %d upstream calls", upstreamCalls)
+	}
+	if w2.Header().Get("X-From-Cache") != "1" {
+		t.Fatalf("expected X-From-Cache on a cache hit, got headers %v", w2.Header())
+	}
+}
+
+func TestHTTPCacheVariesOnVaryHeader(t *testing.T) {
+	var upstreamCalls int
+	upstream := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamCalls++
+		w.Header().Set("Vary", "Accept-Language")
+		w.Write([]byte(r.Header.Get("Accept-Language")))
+	})
+
+	store := NewCache[string, interface{}](1024)
+	defer store.Close()
+	hc := NewHTTPCache(store, upstream)
+
+	reqEN := httptest.NewRequest(http.MethodGet, "/widget", nil)
+	reqEN.Header.Set("Accept-Language", "en")
+	wEN := httptest.NewRecorder()
+	hc.ServeHTTP(wEN, reqEN)
+	if wEN.Body.String() != "en" {
+		t.Fatalf("unexpected body: %q", wEN.Body.String())
+	}
+	if upstreamCalls != 1 {
+		t.Fatalf("expected first en request to hit upstream, got %d calls", upstreamCalls)
+	}
+
+	// A second identical request for the same variant must be a cache hit,
+	// not re-fetched from upstream: store() folds Vary into the key it
+	// saves under, so the initial bare-key lookup has to know to look
+	// there too.
+	wEN2 := httptest.NewRecorder()
+	reqEN2 := httptest.NewRequest(http.MethodGet, "/widget", nil)
+	reqEN2.Header.Set("Accept-Language", "en")
+	hc.ServeHTTP(wEN2, reqEN2)
+	if upstreamCalls != 1 {
+		t.Fatalf("expected second en request to be served from cache, got %d upstream calls", upstreamCalls)
+	}
+	if wEN2.Header().Get("X-From-Cache") != "1" {
+		t.Fatalf("expected X-From-Cache on the repeated en request, got headers %v", wEN2.Header())
+	}
+
+	reqFR := httptest.NewRequest(http.MethodGet, "/widget", nil)
+	reqFR.Header.Set("Accept-Language", "fr")
+	wFR := httptest.NewRecorder()
+	hc.ServeHTTP(wFR, reqFR)
+	if wFR.Body.String() != "fr" {
+		t.Fatalf("expected Vary to keep en/fr responses separate, got %q", wFR.Body.String())
+	}
+	if upstreamCalls != 2 {
+		t.Fatalf("expected the fr variant to be a genuine miss, got %d upstream calls", upstreamCalls)
+	}
+}
+
+func TestHTTPCacheTTLSubtractsAge(t *testing.T) {
+	hc := NewHTTPCache(NewCache[string, interface{}](1024), nil)
+	defer hc.cache.(*Cache[string, interface{}]).Close()
+
+	header := make(http.Header)
+	header.Set("Cache-Control", "max-age=60")
+	header.Set("Age", "55")
+
+	ttl := hc.ttlFor(header)
+	if ttl < 4*time.Second || ttl > 5*time.Second {
+		t.Fatalf("expected ~5s TTL (60s max-age - 55s age), got %s", ttl)
+	}
+}
+
+func TestHTTPCacheTTLAgeFloorsAtZero(t *testing.T) {
+	hc := NewHTTPCache(NewCache[string, interface{}](1024), nil)
+	defer hc.cache.(*Cache[string, interface{}]).Close()
+
+	header := make(http.Header)
+	header.Set("Cache-Control", "max-age=10")
+	header.Set("Age", strconv.Itoa(100))
+
+	if ttl := hc.ttlFor(header); ttl != 0 {
+		t.Fatalf("expected an Age past max-age to floor at 0, got %s", ttl)
+	}
+}
+
+func TestHTTPCacheSkipsNonGET(t *testing.T) {
+	var upstreamCalls int
+	upstream := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamCalls++
+		w.Write([]byte("posted"))
+	})
+
+	store := NewCache[string, interface{}](1024)
+	defer store.Close()
+	hc := NewHTTPCache(store, upstream)
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		hc.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/widget", nil))
+	}
+	if upstreamCalls != 2 {
+		t.Fatalf("expected POST to always hit upstream, got %d calls", upstreamCalls)
+	}
+}
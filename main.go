@@ -1,13 +1,17 @@
 package main
 
 import (
-	"container/list"
+	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
 	"strconv"
-	"sync"
 	"time"
+
+	"github.com/tej112/LRU-Cache-with-Expiration/distributed"
 )
 
 // GOALS
@@ -38,98 +42,70 @@ import (
 // Exipration
 // API
 
-type Cache struct {
-	mu    sync.Mutex         // protects lru and cache
-	lru   *list.List         // doubly linked list
-	cache map[string]Element // map of string to Element
-	size  int                // max size of cache
+type Response struct {
+	Message string `json:"message"`
 }
 
-type Element struct {
-	value *list.Element // pointer to list.Element
-	timer *time.Timer   // pointer to time.Timer
+func (r *Response) String() string {
+	return fmt.Sprintf("{ \"message\": \"%s\" }", r.Message)
 }
 
-func NewCache(size int) *Cache {
-	return &Cache{
-		lru:   list.New(),
-		cache: make(map[string]Element),
-		size:  size,
+// newStore builds the configured Store. backend/addr/dir default to env vars
+// (CACHE_BACKEND, CACHE_REDIS_ADDR, CACHE_BADGER_DIR) so the -backend flag
+// doesn't have to be repeated across deploys.
+func newStore(backend, redisAddr, badgerDir string) Store {
+	switch backend {
+	case "redis":
+		return NewRedisStore(redisAddr)
+	case "badger":
+		store, err := NewBadgerStore(badgerDir)
+		if err != nil {
+			log.Fatalf("open badger store: %v", err)
+		}
+		return store
+	case "memory", "":
+		return NewCache[string, interface{}](1024)
+	default:
+		log.Fatalf("unknown -backend %q (want memory, redis, or badger)", backend)
+		return nil
 	}
 }
 
-func (c *Cache) Get(key string) (*list.Element, bool) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	if e, ok := c.cache[key]; ok {
-		c.lru.MoveToFront(e.value)
-		return e.value, true
+func envDefault(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
 	}
-
-	// c.mu.Unlock()
-	return nil, false
+	return fallback
 }
 
-func (c *Cache) Set(key string, value *list.Element, expiration time.Duration) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	if e, ok := c.cache[key]; ok {
-		c.lru.MoveToFront(e.value) // move to front
-		e.timer.Stop()             // stop timer
-		e.timer = time.AfterFunc(expiration, func() {
-			c.Delete(key)
-		}) // reset timer
-		return
-	}
-
-	if c.lru.Len() >= c.size { // if cache is full
-		c.evict() // evict
+func main() {
+	backend := flag.String("backend", envDefault("CACHE_BACKEND", "memory"), "cache backend: memory, redis, or badger")
+	redisAddr := flag.String("redis-addr", envDefault("CACHE_REDIS_ADDR", "localhost:6379"), "redis address, used when -backend=redis")
+	badgerDir := flag.String("badger-dir", envDefault("CACHE_BADGER_DIR", "./badger-data"), "on-disk directory, used when -backend=badger")
+	gossipAddr := flag.String("gossip-addr", envDefault("CACHE_GOSSIP_ADDR", ""), "redis address for cross-instance invalidation; empty disables it")
+	maxInFlight := flag.Int("max-in-flight", 256, "maximum number of concurrent requests before returning 429")
+	requestTimeout := flag.Duration("request-timeout", envDuration("CACHE_REQUEST_TIMEOUT_MS", 2*time.Second), "per-request deadline")
+	corsConfigPath := flag.String("cors-config", envDefault("CACHE_CORS_CONFIG", ""), "path to a JSON/YAML CORS config file; empty uses DefaultCORSConfig")
+	httpCache := flag.Bool("http-cache", false, "cache GET/HEAD responses RFC 7234-style (Cache-Control, ETag/Last-Modified revalidation, Vary)")
+	httpCacheTTL := flag.Duration("http-cache-ttl", 60*time.Second, "default TTL for responses with no max-age/Expires, used when -http-cache is set")
+	flag.Parse()
+
+	corsConfig, err := LoadCORSConfig(*corsConfigPath)
+	if err != nil {
+		log.Fatalf("load cors config: %v", err)
 	}
 
-	c.lru.PushFront(key) // push to front
-	c.cache[key] = Element{value: value, timer: time.AfterFunc(expiration, func() {
-		c.Delete(key) // delete after expiration
-	})} // add to cache
-}
-
-func (c *Cache) Delete(key string) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	if e, ok := c.cache[key]; ok {
-		c.lru.Remove(e.value) // remove from list
-		e.timer.Stop()        // stop timer
-		delete(c.cache, key)  // delete from cache
-	}
-}
+	cache := newStore(*backend, *redisAddr, *badgerDir)
 
-func (c *Cache) evict() {
-	e := c.lru.Back() // get last element
-	if e != nil {
-		c.lru.Remove(e)                   // remove from list
-		delete(c.cache, e.Value.(string)) // delete from cache
+	var node *distributed.Node
+	if *gossipAddr != "" {
+		transport := distributed.NewRedisTransport(*gossipAddr, "cache-events")
+		node = distributed.NewNode(cache, transport)
+		go node.Run(context.Background())
 	}
-}
-
-func ValueToElement(value string) *list.Element {
-	return &list.Element{Value: value}
-}
-
-type Response struct {
-	Message string `json:"message"`
-}
-
-func (r *Response) String() string {
-	return fmt.Sprintf("{ \"message\": \"%s\" }", r.Message)
-}
-
-func main() {
-	cache := NewCache(1024)
 
 	server := http.NewServeMux()
-	handler := corsMiddleware(server)
+	handler := corsConfig.corsMiddleware(server)
 	server.HandleFunc("/get", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		key := r.URL.Query().Get("key")
@@ -144,9 +120,9 @@ func main() {
 			return
 		}
 
-		if e, ok := cache.Get(key); ok {
+		if v, ok := cache.Get(key); ok {
 			res := Response{
-				Message: e.Value.(string),
+				Message: v.(string),
 			}
 
 			w.Write([]byte(res.String()))
@@ -186,7 +162,7 @@ func main() {
 			return
 		}
 
-		cache.Set(key, ValueToElement(value), expiration)
+		cache.Set(key, value, expiration)
 		res := Response{
 			Message: "key set",
 		}
@@ -194,25 +170,100 @@ func main() {
 
 	})
 
-	// Enable CORS
+	server.HandleFunc("/purge", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		key := r.URL.Query().Get("key")
+		if key == "" {
+			res := Response{Message: "key is required"}
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(res.String()))
+			return
+		}
 
-	http.ListenAndServe(":8080", handler)
-}
+		if node != nil {
+			if err := node.Delete(r.Context(), key); err != nil {
+				res := Response{Message: fmt.Sprintf("purge failed: %s", err)}
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(res.String()))
+				return
+			}
+		} else {
+			cache.Delete(key)
+		}
+
+		res := Response{Message: "key purged"}
+		w.Write([]byte(res.String()))
+	})
 
-func corsMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Set CORS headers
-		w.Header().Set("Access-Control-Allow-Origin", "*")                                                                            // Allow requests from any origin
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS, PUT, DELETE")                                             // Define the allowed methods
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Access-Control-Allow-Headers, Authorization, X-Requested-With") // Define the allowed headers
+	server.HandleFunc("/mset", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
 
-		// Handle preflight OPTIONS requests
-		if r.Method == http.MethodOptions {
-			w.WriteHeader(http.StatusOK)
+		var body struct {
+			Items []struct {
+				Key   string `json:"key"`
+				Value string `json:"value"`
+				Exp   int64  `json:"exp"` // milliseconds, same unit as /set's "exp"
+			} `json:"items"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(Response{Message: "invalid JSON body"})
 			return
 		}
 
-		// Call the next handler in the chain
-		next.ServeHTTP(w, r)
+		items := make([]StoreBatchItem, 0, len(body.Items))
+		for _, item := range body.Items {
+			if item.Key == "" {
+				continue
+			}
+			items = append(items, StoreBatchItem{
+				Key:        item.Key,
+				Value:      item.Value,
+				Expiration: time.Duration(item.Exp) * time.Millisecond,
+			})
+		}
+
+		cache.SetBatch(items)
+		json.NewEncoder(w).Encode(Response{Message: fmt.Sprintf("%d keys set", len(items))})
 	})
+
+	server.HandleFunc("/mget", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var body struct {
+			Keys []string `json:"keys"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(Response{Message: "invalid JSON body"})
+			return
+		}
+
+		values := cache.GetBatch(body.Keys)
+		json.NewEncoder(w).Encode(struct {
+			Values map[string]interface{} `json:"values"`
+		}{Values: values})
+	})
+
+	// MaxInFlight wraps first (innermost) so its semaphore slot is held for
+	// as long as the real handler actually runs, even past the point where
+	// TimeoutHandler (wrapped around it) gives up on it and answers the client.
+	// Wrapping the other way around releases the slot as soon as the client
+	// sees a response, which under-counts genuinely in-flight work.
+	handler = MaxInFlightHandler(handler, *maxInFlight)
+	handler = TimeoutHandler(handler, *requestTimeout)
+
+	// HTTPCache wraps outermost, using its own store so its "METHOD URL"
+	// keys can't collide with the plain keys /get and /set read and write
+	// on cache. Wrapping outside MaxInFlight/TimeoutHandler means a cache
+	// hit answers without consuming an in-flight slot or timeout-goroutine
+	// overhead; a miss still runs the full protected chain underneath.
+	if *httpCache {
+		httpCacheStore := NewCache[string, interface{}](1024)
+		respCache := NewHTTPCache(httpCacheStore, handler)
+		respCache.DefaultTTL = *httpCacheTTL
+		handler = respCache
+	}
+
+	http.ListenAndServe(":8080", handler)
 }
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MaxInFlightHandler caps the number of requests that can be in flight at
+// once using a buffered channel as a semaphore. Once it's full, incoming
+// requests fail fast with 429 instead of queuing behind c.mu for slow work.
+func MaxInFlightHandler(next http.Handler, max int) http.Handler {
+	sem := make(chan struct{}, max)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case sem <- struct{}{}:
+			defer func() { <-sem }()
+			next.ServeHTTP(w, r)
+		default:
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{ "message": "too many in-flight requests" }`))
+		}
+	})
+}
+
+// TimeoutHandler enforces a per-request deadline. The downstream handler's
+// context is canceled when the deadline passes, so cache operations reading
+// ctx can abort instead of running unbounded.
+func TimeoutHandler(next http.Handler, timeout time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		tw := &timeoutWriter{ResponseWriter: w}
+		done := make(chan struct{})
+		go func() {
+			next.ServeHTTP(tw, r.WithContext(ctx))
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			if tw.markTimedOut() {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusGatewayTimeout)
+				w.Write([]byte(`{ "message": "request timed out" }`))
+			}
+		}
+	})
+}
+
+// timeoutWriter guards against the downstream handler still writing to w
+// after TimeoutHandler has already sent the timeout response: once timedOut
+// is set, Write/WriteHeader drop the abandoned goroutine's output instead of
+// forwarding it and corrupting the response already sent to the client.
+type timeoutWriter struct {
+	http.ResponseWriter
+	mu          sync.Mutex
+	wroteHeader bool
+	timedOut    bool
+}
+
+// markTimedOut reports whether TimeoutHandler should write the timeout
+// response itself: false means the real handler has already started writing
+// its response (or we already timed out once), so the timeout response must
+// not be sent on top of it.
+func (tw *timeoutWriter) markTimedOut() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.wroteHeader || tw.timedOut {
+		return false
+	}
+	tw.timedOut = true
+	return true
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+	tw.wroteHeader = true
+	tw.ResponseWriter.WriteHeader(code)
+}
+
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if !tw.wroteHeader {
+		tw.wroteHeader = true
+	}
+	return tw.ResponseWriter.Write(p)
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if ms, err := strconv.Atoi(v); err == nil {
+			return time.Duration(ms) * time.Millisecond
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore is a Store backed by Redis, so multiple API pods can share one
+// cache. Values are kept as strings via SETEX for TTL; a sorted set tracks
+// recency (score = last-touched unix nanos) so Keys() can report an LRU
+// order without needing a separate eviction pass (Redis's own maxmemory
+// policy handles eviction under memory pressure).
+//
+// RedisStore does not enforce the 1024-key cap or any LRU eviction of its
+// own; the recency set only reports an order for Keys(). Keeping it bounded
+// is Redis's job (maxmemory + an eviction policy), and Len() via DBSIZE
+// counts the recency set itself plus anything else sharing that Redis DB.
+type RedisStore struct {
+	rdb        *redis.Client
+	ctx        context.Context
+	recencySet string
+}
+
+func NewRedisStore(addr string) *RedisStore {
+	return &RedisStore{
+		rdb:        redis.NewClient(&redis.Options{Addr: addr}),
+		ctx:        context.Background(),
+		recencySet: "cache:recency",
+	}
+}
+
+func (s *RedisStore) Get(key string) (interface{}, bool) {
+	val, err := s.rdb.Get(s.ctx, key).Result()
+	if err != nil {
+		return nil, false
+	}
+	s.rdb.ZAdd(s.ctx, s.recencySet, redis.Z{Score: float64(time.Now().UnixNano()), Member: key})
+	return val, true
+}
+
+// Set stores value with a TTL of expiration. expiration <= 0 means "keep
+// forever": Redis's SETEX rejects a non-positive TTL outright, so that case
+// falls back to a plain SET instead of silently dropping the key.
+func (s *RedisStore) Set(key string, value interface{}, expiration time.Duration) {
+	var err error
+	if expiration > 0 {
+		err = s.rdb.SetEx(s.ctx, key, fmt.Sprintf("%v", value), expiration).Err()
+	} else {
+		err = s.rdb.Set(s.ctx, key, fmt.Sprintf("%v", value), 0).Err()
+	}
+	if err != nil {
+		log.Printf("redis store: set %q: %v", key, err)
+		return
+	}
+	if err := s.rdb.ZAdd(s.ctx, s.recencySet, redis.Z{Score: float64(time.Now().UnixNano()), Member: key}).Err(); err != nil {
+		log.Printf("redis store: track recency for %q: %v", key, err)
+	}
+}
+
+func (s *RedisStore) Delete(key string) {
+	s.rdb.Del(s.ctx, key)
+	s.rdb.ZRem(s.ctx, s.recencySet, key)
+}
+
+func (s *RedisStore) Len() int {
+	n, err := s.rdb.DBSize(s.ctx).Result()
+	if err != nil {
+		return 0
+	}
+	return int(n)
+}
+
+func (s *RedisStore) Keys() []string {
+	// Most-recently-used first.
+	keys, err := s.rdb.ZRevRange(s.ctx, s.recencySet, 0, -1).Result()
+	if err != nil {
+		return nil
+	}
+	return keys
+}
+
+// SetBatch and GetBatch just loop: Redis has no local mutex to amortize, and
+// pipelining is future work if this becomes a hot path.
+
+func (s *RedisStore) SetBatch(items []StoreBatchItem) {
+	for _, item := range items {
+		s.Set(item.Key, item.Value, item.Expiration)
+	}
+}
+
+func (s *RedisStore) GetBatch(keys []string) map[string]interface{} {
+	out := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		if v, ok := s.Get(key); ok {
+			out[key] = v
+		}
+	}
+	return out
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestRedisStoreZeroExpiration(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mr.Close()
+
+	s := NewRedisStore(mr.Addr())
+	s.Set("k", "v", 0)
+
+	if _, ok := s.Get("k"); !ok {
+		t.Fatalf("expected Set with exp=0 to store the key permanently (Redis SETEX rejects ttl<=0)")
+	}
+}
+
+func TestMemoryCacheZeroExpiration(t *testing.T) {
+	c := NewCache[string, interface{}](1024)
+	defer c.Close()
+	c.Set("k", "v", 0)
+	time.Sleep(2 * time.Millisecond)
+	if _, ok := c.Get("k"); !ok {
+		t.Fatalf("expected exp=0 to mean \"never expires\", consistent with BadgerStore/RedisStore")
+	}
+}
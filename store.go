@@ -0,0 +1,25 @@
+package main
+
+import "time"
+
+// StoreBatchItem is one key/value/expiration triple for Store.SetBatch.
+type StoreBatchItem = BatchItem[string, interface{}]
+
+// Store is the backend contract the HTTP layer depends on. Cache[string,
+// interface{}] (the in-memory LRU) is the default implementation; RedisStore
+// and BadgerStore are swap-in alternatives for horizontal scaling and
+// on-disk persistence.
+type Store interface {
+	Get(key string) (interface{}, bool)
+	Set(key string, value interface{}, expiration time.Duration)
+	Delete(key string)
+	Len() int
+	Keys() []string
+
+	// SetBatch and GetBatch exist so /mset and /mget can avoid one
+	// lock acquisition per key. Cache[string, interface{}] does this for
+	// real; Redis/Badger just loop since there's no shared in-process lock
+	// to amortize.
+	SetBatch(items []StoreBatchItem)
+	GetBatch(keys []string) map[string]interface{}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTimeoutHandlerWriteAfterTimeoutDoesNotCorruptResponse(t *testing.T) {
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond) // longer than the handler timeout below
+		w.Write([]byte("LATE-WRITE-FROM-SLOW-HANDLER"))
+	})
+
+	h := TimeoutHandler(slow, 10*time.Millisecond)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/get", nil)
+	h.ServeHTTP(rec, req)
+
+	// Give the abandoned goroutine time to finish and attempt its late write,
+	// which timeoutWriter must drop.
+	time.Sleep(100 * time.Millisecond)
+
+	body := rec.Body.String()
+	t.Logf("response body after timeout + late write: %q", body)
+	if len(body) > len(`{ "message": "request timed out" }`) {
+		t.Fatalf("BUG: response body was corrupted by a write from the abandoned handler goroutine after the timeout response was already sent: %q", body)
+	}
+}